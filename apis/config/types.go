@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MetricProviderType is the type of the metrics provider used by trimaran plugins.
+type MetricProviderType string
+
+const (
+	KubernetesMetricsServer MetricProviderType = "KubernetesMetricsServer"
+	Prometheus              MetricProviderType = "Prometheus"
+	SignalFx                MetricProviderType = "SignalFx"
+)
+
+// MetricProviderSpec describes where and how to reach a metrics provider.
+type MetricProviderSpec struct {
+	// Type is the type of the metrics provider
+	Type MetricProviderType
+	// Address is the address of the metrics provider
+	Address string
+	// Token is the authentication token of the metrics provider
+	Token string
+	// InsecureSkipVerify indicates whether to skip TLS verification
+	InsecureSkipVerify *bool
+}
+
+// TrimaranSpec holds the common configuration shared by all trimaran plugins.
+type TrimaranSpec struct {
+	// WatcherAddress is the address of a load-watcher instance. If empty, the plugin
+	// falls back to MetricProvider.
+	WatcherAddress *string
+	// MetricProvider configures the metrics source used when WatcherAddress is unset.
+	MetricProvider MetricProviderSpec
+}
+
+// PowerSavingArgs holds arguments used to configure the PowerSaving scheduler
+// plugin. Fields are grouped by the feature that owns them (Kepler, Prod/BE
+// separation, metric freshness, multi-resource weighting); each group's doc
+// comment explains that feature on its own, since the groups were added and
+// consumed by separate changes over time.
+type PowerSavingArgs struct {
+	metav1.TypeMeta
+
+	TrimaranSpec
+
+	// RequestsMilliCores assigns a default CPU utilization for a pod if its CPU
+	// request is not specified.
+	RequestsMilliCores int64
+	// RequestsMultiplier scales a pod's CPU and memory requests when no limit is
+	// set, to account for burst usage above the request.
+	RequestsMultiplier float64
+	// HighCPUThreshold marks a node as already highly utilised. A node at or above
+	// this value is scored by its raw utilization percent, which ranks it at or
+	// above every node below the threshold.
+	HighCPUThreshold int64
+	// LowCPUThreshold marks a node as underutilised. At or below this value, the
+	// plugin boosts the node's score so idle hardware is consolidated onto rather than
+	// spread across.
+	LowCPUThreshold int64
+
+	// KeplerEnabled turns on Kepler-backed power metrics as the scoring signal in
+	// place of the plain CPU-utilization heuristic.
+	KeplerEnabled bool
+	// KeplerEndpoint is the base URL of the Kepler Prometheus endpoint, e.g.
+	// "http://kepler.kepler-system:9102".
+	KeplerEndpoint string
+	// KeplerQuery overrides the default PromQL query used to fetch per-node Kepler
+	// joules counters. Leave empty to use the built-in query.
+	KeplerQuery string
+	// KeplerScrapeIntervalSeconds controls how often Kepler is polled.
+	KeplerScrapeIntervalSeconds int64
+	// KeplerMinSamples is the minimum number of (utilization, watts) samples a node
+	// must have accumulated before its learned power model is trusted. Nodes below
+	// this threshold fall back to the CPU heuristic.
+	KeplerMinSamples int32
+	// NodeIdleWattsLabel, when set, names a node label carrying an operator-supplied
+	// idle power draw in watts, used instead of the online least-squares estimate.
+	NodeIdleWattsLabel string
+	// NodeWattsPerCoreLabel, when set, names a node label carrying an
+	// operator-supplied watts-per-CPU-percent slope, used instead of the online
+	// least-squares estimate.
+	NodeWattsPerCoreLabel string
+
+	// ProdHighCPUThreshold and ProdLowCPUThreshold are stricter variants of
+	// HighCPUThreshold/LowCPUThreshold applied to Prod pods so that latency
+	// sensitive workloads are kept off hosts that are already warming up.
+	ProdHighCPUThreshold int64
+	ProdLowCPUThreshold  int64
+	// AggregatedUsage selects a percentile profile ("p95" or "p99") read from the
+	// collector's aggregated metrics for Prod pods, instead of the latest sample.
+	// Empty disables aggregation and keeps using the latest sample.
+	AggregatedUsage string
+	// ProdPriorityClasses lists the PriorityClassNames treated as Prod. A pod whose
+	// PriorityClassName is not listed is checked against ProdLabelKey/ProdLabelValue.
+	ProdPriorityClasses []string
+	// ProdLabelKey/ProdLabelValue identify a Prod pod via label when its
+	// PriorityClassName is not in ProdPriorityClasses. An empty ProdLabelKey
+	// disables the label check.
+	ProdLabelKey   string
+	ProdLabelValue string
+
+	// NodeMetricExpirationSeconds bounds how old a metrics window may be before it
+	// is treated as missing. Defaults to 180.
+	NodeMetricExpirationSeconds int64
+	// FallbackToRequests switches stale-metric nodes from MinNodeScore to
+	// allocation-based packing, estimating utilization from summed pod requests
+	// over node allocatable.
+	FallbackToRequests bool
+	// StrictFreshness causes nodes with an expired metrics window to be filtered
+	// out entirely instead of merely scored low.
+	StrictFreshness bool
+
+	// ResourceWeights combines per-resource normalized utilization into a single
+	// score. Resources absent from the map are ignored. When empty, CPU alone is
+	// used with weight 1.
+	ResourceWeights map[v1.ResourceName]float64
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PowerSavingArgs) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerSavingArgs)
+	*out = *in
+	if in.WatcherAddress != nil {
+		addr := *in.WatcherAddress
+		out.WatcherAddress = &addr
+	}
+	if in.MetricProvider.InsecureSkipVerify != nil {
+		skipVerify := *in.MetricProvider.InsecureSkipVerify
+		out.MetricProvider.InsecureSkipVerify = &skipVerify
+	}
+	if in.ProdPriorityClasses != nil {
+		out.ProdPriorityClasses = append([]string(nil), in.ProdPriorityClasses...)
+	}
+	if in.ResourceWeights != nil {
+		out.ResourceWeights = make(map[v1.ResourceName]float64, len(in.ResourceWeights))
+		for k, v := range in.ResourceWeights {
+			out.ResourceWeights[k] = v
+		}
+	}
+	return out
+}
+
+// Namespaces restricts which namespaces a descheduling plugin considers when
+// picking evictable pods.
+type Namespaces struct {
+	Include []string
+	Exclude []string
+}
+
+// PowerSavingConsolidationArgs holds arguments used to configure the
+// PowerSavingConsolidation descheduler plugin.
+type PowerSavingConsolidationArgs struct {
+	metav1.TypeMeta
+
+	TrimaranSpec
+
+	// LowCPUThreshold marks a node as underutilised and a candidate to be
+	// drained and powered down.
+	LowCPUThreshold int64
+	// HighCPUThreshold marks a node as overutilised; it is never used as a
+	// consolidation target for evicted pods.
+	HighCPUThreshold int64
+
+	// MaxNoOfPodsToEvictPerNode caps how many pods are evicted from a single
+	// underutilized node in one descheduling cycle. Zero means unlimited.
+	MaxNoOfPodsToEvictPerNode int32
+	// NumberOfNodes caps how many underutilized nodes are processed in a single
+	// descheduling cycle, bounding work on large clusters. Zero means unlimited.
+	NumberOfNodes int32
+
+	// EvictableNamespaces restricts eviction to (or excludes eviction from) a set
+	// of namespaces.
+	EvictableNamespaces Namespaces
+	// ThresholdPriorityClassName excludes pods at or above this priority class
+	// from eviction, in addition to the usual DaemonSet/static-pod guards.
+	// When unset, pods at or above system-cluster-critical priority are
+	// protected by default; set this to a lower-priority class to protect
+	// more, or to a higher one to narrow the protection.
+	ThresholdPriorityClassName string
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PowerSavingConsolidationArgs) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerSavingConsolidationArgs)
+	*out = *in
+	if in.WatcherAddress != nil {
+		addr := *in.WatcherAddress
+		out.WatcherAddress = &addr
+	}
+	if in.MetricProvider.InsecureSkipVerify != nil {
+		skipVerify := *in.MetricProvider.InsecureSkipVerify
+		out.MetricProvider.InsecureSkipVerify = &skipVerify
+	}
+	if in.EvictableNamespaces.Include != nil {
+		out.EvictableNamespaces.Include = append([]string(nil), in.EvictableNamespaces.Include...)
+	}
+	if in.EvictableNamespaces.Exclude != nil {
+		out.EvictableNamespaces.Exclude = append([]string(nil), in.EvictableNamespaces.Exclude...)
+	}
+	return out
+}
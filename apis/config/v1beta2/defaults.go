@@ -0,0 +1,38 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+// Default values for PowerSavingArgs.
+const (
+	DefaultRequestsMilliCores = int64(1000)
+	// DefaultRequestsBytes is used for default requested memory when a pod has
+	// neither a memory request nor limit specified.
+	DefaultRequestsBytes    = int64(2 * 1024 * 1024 * 1024) // 2Gi
+	DefaultLowCPUThreshold  = int64(20)
+	DefaultHighCPUThreshold = int64(80)
+
+	// DefaultKeplerScrapeIntervalSeconds is how often Kepler's Prometheus endpoint
+	// is polled when KeplerEnabled is true.
+	DefaultKeplerScrapeIntervalSeconds = int64(30)
+	// DefaultKeplerMinSamples is the minimum number of (utilization, watts) samples
+	// required before a node's learned power model is trusted.
+	DefaultKeplerMinSamples = int32(5)
+
+	// DefaultNodeMetricExpirationSeconds bounds how old a metrics window may be
+	// before it is treated as missing.
+	DefaultNodeMetricExpirationSeconds = int64(180)
+)
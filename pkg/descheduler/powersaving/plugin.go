@@ -0,0 +1,352 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+powersaving package provides a descheduler plugin that partners with the
+PowerSaving scheduler plugin to consolidate workloads: it evicts pods off
+underutilized nodes so they can eventually be powered down, mirroring the
+Volcano low_node_utilization rescheduling design.
+
+NOTE: registering PowerSavingConsolidation with the descheduler's plugin
+registry/scheme is not part of this change series and has to land as a
+follow-up in the descheduler binary that wires up its plugin set; until then
+this plugin is only reachable by tests/tools that construct it directly via
+New and call Deschedule themselves.
+*/
+package powersaving
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/klog/v2"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran/powersaving"
+)
+
+const Name = "PowerSavingConsolidation"
+
+// defaultThresholdPriority protects pods at or above system-cluster-critical
+// priority (the lower of the two built-in system-critical PriorityClasses,
+// scheduling.SystemCriticalPriority upstream) from eviction when
+// ThresholdPriorityClassName is left unset, so a default configuration never
+// drains CoreDNS/CNI-controller-style pods off a node.
+const defaultThresholdPriority = int32(2000000000)
+
+// NodeClass buckets a node by CPU utilization for consolidation purposes.
+type NodeClass int
+
+const (
+	Underutilized NodeClass = iota
+	Appropriate
+	Overutilized
+)
+
+// PowerSavingConsolidation evicts evictable pods off underutilized nodes so
+// their hosts become fully drained and can be powered down via NodePowerOffHook.
+type PowerSavingConsolidation struct {
+	client            kubernetes.Interface
+	podLister         corelisters.PodLister
+	pdbLister         policylisters.PodDisruptionBudgetLister
+	collector         *trimaran.Collector
+	args              *pluginConfig.PowerSavingConsolidationArgs
+	hook              NodePowerOffHook
+	thresholdPriority int32
+}
+
+// New builds a PowerSavingConsolidation plugin. hook may be nil, in which case
+// fully drained nodes are logged but never powered off.
+func New(
+	client kubernetes.Interface,
+	podLister corelisters.PodLister,
+	pdbLister policylisters.PodDisruptionBudgetLister,
+	args *pluginConfig.PowerSavingConsolidationArgs,
+	hook NodePowerOffHook,
+) (*PowerSavingConsolidation, error) {
+	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdPriority := defaultThresholdPriority
+	if args.ThresholdPriorityClassName != "" {
+		pc, err := client.SchedulingV1().PriorityClasses().Get(context.TODO(), args.ThresholdPriorityClassName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("resolving ThresholdPriorityClassName %q: %w", args.ThresholdPriorityClassName, err)
+		}
+		thresholdPriority = pc.Value
+	}
+
+	return &PowerSavingConsolidation{
+		client:            client,
+		podLister:         podLister,
+		pdbLister:         pdbLister,
+		collector:         collector,
+		args:              args,
+		hook:              hook,
+		thresholdPriority: thresholdPriority,
+	}, nil
+}
+
+func (d *PowerSavingConsolidation) Name() string {
+	return Name
+}
+
+// Deschedule classifies nodes into underutilized/appropriate/overutilized and
+// evicts evictable pods off underutilized nodes, simulating with
+// PredictUtilisation whether each candidate target node would tip over
+// HighCPUThreshold before committing to an eviction. Nodes that end up fully
+// drained are handed to the configured NodePowerOffHook.
+func (d *PowerSavingConsolidation) Deschedule(ctx context.Context, nodes []*v1.Node) error {
+	classes := d.classifyNodes(nodes)
+
+	underutilized := classes[Underutilized]
+	if d.args.NumberOfNodes > 0 && int32(len(underutilized)) > d.args.NumberOfNodes {
+		underutilized = underutilized[:d.args.NumberOfNodes]
+	}
+
+	// targetUtilEstimate tracks a running CPU utilization estimate per
+	// candidate target node across this entire Deschedule call, seeded from
+	// the live collector reading and incremented as each pod is "virtually"
+	// assigned to a target, so later pods (from this source node or any
+	// other underutilized node processed afterwards) see the effect of
+	// evictions already committed to in this cycle instead of all
+	// independently picking the same node off its stale live reading.
+	targetUtilEstimate := d.seedTargetUtilEstimate(classes)
+
+	for _, node := range underutilized {
+		pods, err := d.evictablePodsOnNode(node)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list pods for node", "nodeName", node.Name)
+			continue
+		}
+
+		evicted := 0
+		for _, pod := range pods {
+			if d.args.MaxNoOfPodsToEvictPerNode > 0 && int32(evicted) >= d.args.MaxNoOfPodsToEvictPerNode {
+				break
+			}
+			target := d.pickConsolidationTarget(pod, classes, node.Name, targetUtilEstimate)
+			if target == nil {
+				klog.V(4).InfoS("No consolidation target would stay under HighCPUThreshold; leaving pod in place", "pod", klog.KObj(pod), "nodeName", node.Name)
+				continue
+			}
+			if err := d.evict(ctx, pod); err != nil {
+				klog.ErrorS(err, "Failed to evict pod", "pod", klog.KObj(pod))
+				continue
+			}
+			evicted++
+		}
+
+		if evicted == len(pods) && d.hook != nil {
+			if err := d.hook.PowerOff(ctx, node); err != nil {
+				klog.ErrorS(err, "Failed to power off fully drained node", "nodeName", node.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// classifyNodes buckets nodes by CPU utilization reported by the shared
+// trimaran Collector, the same source the PowerSaving scorer reads from.
+func (d *PowerSavingConsolidation) classifyNodes(nodes []*v1.Node) map[NodeClass][]*v1.Node {
+	classes := map[NodeClass][]*v1.Node{}
+	for _, node := range nodes {
+		util, ok := d.nodeCPUUtilPercent(node)
+		if !ok {
+			continue
+		}
+		switch {
+		case util > float64(d.args.HighCPUThreshold):
+			classes[Overutilized] = append(classes[Overutilized], node)
+		case util < float64(d.args.LowCPUThreshold):
+			classes[Underutilized] = append(classes[Underutilized], node)
+		default:
+			classes[Appropriate] = append(classes[Appropriate], node)
+		}
+	}
+	return classes
+}
+
+func (d *PowerSavingConsolidation) nodeCPUUtilPercent(node *v1.Node) (float64, bool) {
+	metrics, _ := d.collector.GetNodeMetrics(node.Name)
+	for _, metric := range metrics {
+		if metric.Type == watcher.CPU && (metric.Operator == watcher.Average || metric.Operator == watcher.Latest) {
+			return metric.Value, true
+		}
+	}
+	return 0, false
+}
+
+// seedTargetUtilEstimate seeds the per-cycle running CPU utilization estimate
+// for every Appropriate-or-Underutilized node (the candidate consolidation
+// targets) from the live collector reading. pickConsolidationTarget updates
+// these estimates in place as it assigns pods, instead of re-reading the same
+// live metrics for every evicted pod.
+func (d *PowerSavingConsolidation) seedTargetUtilEstimate(classes map[NodeClass][]*v1.Node) map[string]float64 {
+	estimate := make(map[string]float64)
+	candidates := append(append([]*v1.Node{}, classes[Appropriate]...), classes[Underutilized]...)
+	for _, candidate := range candidates {
+		if util, ok := d.nodeCPUUtilPercent(candidate); ok {
+			estimate[candidate.Name] = util
+		}
+	}
+	return estimate
+}
+
+// pickConsolidationTarget simulates adding pod's predicted CPU usage to each
+// appropriate-or-underutilized node (excluding the source node) and returns
+// the first one that would stay at or below HighCPUThreshold, or nil if none
+// would. targetUtilEstimate is the running per-cycle utilization estimate
+// seeded by seedTargetUtilEstimate; the chosen target's entry is incremented
+// by this pod's predicted delta so the next call in this cycle sees it.
+func (d *PowerSavingConsolidation) pickConsolidationTarget(pod *v1.Pod, classes map[NodeClass][]*v1.Node, sourceNode string, targetUtilEstimate map[string]float64) *v1.Node {
+	var podDeltaMillis int64
+	for i := range pod.Spec.Containers {
+		podDeltaMillis += powersaving.PredictUtilisation(&pod.Spec.Containers[i])
+	}
+	podDeltaMillis += pod.Spec.Overhead.Cpu().MilliValue()
+
+	candidates := append(append([]*v1.Node{}, classes[Appropriate]...), classes[Underutilized]...)
+	for _, candidate := range candidates {
+		if candidate.Name == sourceNode {
+			continue
+		}
+		util, ok := targetUtilEstimate[candidate.Name]
+		if !ok {
+			continue
+		}
+		capMillis := float64(candidate.Status.Capacity.Cpu().MilliValue())
+		if capMillis == 0 {
+			continue
+		}
+		predictedUtil := util + 100*float64(podDeltaMillis)/capMillis
+		if predictedUtil <= float64(d.args.HighCPUThreshold) {
+			targetUtilEstimate[candidate.Name] = predictedUtil
+			return candidate
+		}
+	}
+	return nil
+}
+
+// evictablePodsOnNode lists the pods on node that are safe to evict: not
+// DaemonSet-managed, not static/mirror pods, not above ThresholdPriorityClassName,
+// and within EvictableNamespaces.
+func (d *PowerSavingConsolidation) evictablePodsOnNode(node *v1.Node) ([]*v1.Pod, error) {
+	all, err := d.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var evictable []*v1.Pod
+	for _, pod := range all {
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+		if !d.isEvictable(pod) {
+			continue
+		}
+		evictable = append(evictable, pod)
+	}
+	return evictable, nil
+}
+
+func (d *PowerSavingConsolidation) isEvictable(pod *v1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	if source, isStatic := pod.Annotations["kubernetes.io/config.source"]; isStatic && source != "api" {
+		return false
+	}
+	if !namespaceAllowed(d.args.EvictableNamespaces, pod.Namespace) {
+		return false
+	}
+	if pod.Spec.Priority != nil && *pod.Spec.Priority >= d.thresholdPriority {
+		return false
+	}
+	return true
+}
+
+// namespaceAllowed applies Include/Exclude filtering: an Include list, when
+// non-empty, is the sole allow-list; otherwise Exclude removes namespaces from
+// an implicit allow-all.
+func namespaceAllowed(n pluginConfig.Namespaces, namespace string) bool {
+	if len(n.Include) > 0 {
+		for _, ns := range n.Include {
+			if ns == namespace {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ns := range n.Exclude {
+		if ns == namespace {
+			return false
+		}
+	}
+	return true
+}
+
+// evict checks the pod's PodDisruptionBudget (if any) before creating an
+// eviction, to respect the same guard any other evictor would.
+func (d *PowerSavingConsolidation) evict(ctx context.Context, pod *v1.Pod) error {
+	if blocked, err := d.blockedByPDB(pod); err != nil {
+		return err
+	} else if blocked {
+		return fmt.Errorf("pod %s/%s is blocked by a PodDisruptionBudget", pod.Namespace, pod.Name)
+	}
+
+	eviction := &policyv1.Eviction{
+		ObjectMeta: pod.ObjectMeta,
+	}
+	return d.client.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+}
+
+func (d *PowerSavingConsolidation) blockedByPDB(pod *v1.Pod) (bool, error) {
+	if d.pdbLister == nil {
+		return false, nil
+	}
+	pdbs, err := d.pdbLister.PodDisruptionBudgets(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			klog.ErrorS(err, "Failed to parse PodDisruptionBudget selector", "pdb", klog.KObj(pdb))
+			continue
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed < 1 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func nodeWithCPUCapacity(name string, millis int64) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU: *resource.NewMilliQuantity(millis, resource.DecimalSI),
+			},
+		},
+	}
+}
+
+func podRequestingCPU(millis int64) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Limits: v1.ResourceList{
+							v1.ResourceCPU: *resource.NewMilliQuantity(millis, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPickConsolidationTargetSkipsSourceNode(t *testing.T) {
+	d := &PowerSavingConsolidation{args: &pluginConfig.PowerSavingConsolidationArgs{HighCPUThreshold: 80}}
+	source := nodeWithCPUCapacity("source", 4000)
+	classes := map[NodeClass][]*v1.Node{Underutilized: {source}}
+	estimate := map[string]float64{"source": 10}
+
+	target := d.pickConsolidationTarget(podRequestingCPU(1000), classes, "source", estimate)
+	if target != nil {
+		t.Errorf("pickConsolidationTarget returned %v, want nil when the only candidate is the source node", target)
+	}
+}
+
+func TestPickConsolidationTargetRejectsNodeThatWouldTipOver(t *testing.T) {
+	d := &PowerSavingConsolidation{args: &pluginConfig.PowerSavingConsolidationArgs{HighCPUThreshold: 80}}
+	hot := nodeWithCPUCapacity("hot", 1000)
+	classes := map[NodeClass][]*v1.Node{Appropriate: {hot}}
+	estimate := map[string]float64{"hot": 75}
+
+	// Adding a 1000m pod to a 1000m-capacity node already at 75% would push it
+	// to 175%, well over the 80% threshold.
+	target := d.pickConsolidationTarget(podRequestingCPU(1000), classes, "source", estimate)
+	if target != nil {
+		t.Errorf("pickConsolidationTarget returned %v, want nil when every candidate would exceed HighCPUThreshold", target)
+	}
+}
+
+func TestPickConsolidationTargetTracksRunningEstimateAcrossCalls(t *testing.T) {
+	d := &PowerSavingConsolidation{args: &pluginConfig.PowerSavingConsolidationArgs{HighCPUThreshold: 80}}
+	target := nodeWithCPUCapacity("target", 1000)
+	classes := map[NodeClass][]*v1.Node{Appropriate: {target}}
+	estimate := map[string]float64{"target": 50}
+
+	// A first 200m pod fits (50% + 20% = 70% <= 80%) and must bump the running
+	// estimate so a second pod evaluated in the same cycle sees the updated
+	// utilization instead of the original stale 50% reading.
+	first := d.pickConsolidationTarget(podRequestingCPU(200), classes, "source", estimate)
+	if first == nil || first.Name != "target" {
+		t.Fatalf("first pickConsolidationTarget = %v, want target", first)
+	}
+	if got, want := estimate["target"], 70.0; got != want {
+		t.Fatalf("estimate[target] after first pick = %v, want %v", got, want)
+	}
+
+	// A second 200m pod would now push the node to 90%, over HighCPUThreshold,
+	// so it must not be picked again even though the live metrics (50%) never
+	// changed.
+	second := d.pickConsolidationTarget(podRequestingCPU(200), classes, "source", estimate)
+	if second != nil {
+		t.Fatalf("second pickConsolidationTarget = %v, want nil: target should already be accounted as full for this cycle", second)
+	}
+}
+
+func TestNamespaceAllowed(t *testing.T) {
+	cases := []struct {
+		name      string
+		ns        pluginConfig.Namespaces
+		namespace string
+		want      bool
+	}{
+		{"empty allows everything", pluginConfig.Namespaces{}, "default", true},
+		{"include allow-lists", pluginConfig.Namespaces{Include: []string{"team-a"}}, "team-a", true},
+		{"include excludes everything else", pluginConfig.Namespaces{Include: []string{"team-a"}}, "team-b", false},
+		{"exclude removes from allow-all", pluginConfig.Namespaces{Exclude: []string{"kube-system"}}, "kube-system", false},
+		{"exclude allows everything else", pluginConfig.Namespaces{Exclude: []string{"kube-system"}}, "default", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := namespaceAllowed(c.ns, c.namespace); got != c.want {
+				t.Errorf("namespaceAllowed(%+v, %q) = %v, want %v", c.ns, c.namespace, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// NodePowerOffHook is implemented by operators to actually cordon, drain and
+// power off a node that this plugin has fully drained of evictable pods. The
+// plugin itself never touches node power state directly.
+type NodePowerOffHook interface {
+	PowerOff(ctx context.Context, node *v1.Node) error
+}
+
+// WebhookPowerOffHook powers off a node by POSTing its name to an operator
+// supplied URL.
+type WebhookPowerOffHook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPowerOffHook builds a hook that calls url with a JSON body of
+// {"node": "<name>"} whenever a node is fully drained.
+func NewWebhookPowerOffHook(url string) *WebhookPowerOffHook {
+	return &WebhookPowerOffHook{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (h *WebhookPowerOffHook) PowerOff(ctx context.Context, node *v1.Node) error {
+	body, err := json.Marshal(map[string]string{"node": node.Name})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("power-off webhook for node %q returned status %d", node.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// CommandPowerOffHook powers off a node by running an operator supplied
+// command, substituting the node name for the literal "{{.Node}}" token in its
+// arguments.
+type CommandPowerOffHook struct {
+	command string
+	args    []string
+}
+
+// NewCommandPowerOffHook builds a hook that execs command with args, replacing
+// "{{.Node}}" with the target node's name.
+func NewCommandPowerOffHook(command string, args []string) *CommandPowerOffHook {
+	return &CommandPowerOffHook{command: command, args: args}
+}
+
+func (h *CommandPowerOffHook) PowerOff(ctx context.Context, node *v1.Node) error {
+	resolvedArgs := make([]string, len(h.args))
+	for i, arg := range h.args {
+		resolvedArgs[i] = strings.ReplaceAll(arg, "{{.Node}}", node.Name)
+	}
+	cmd := exec.CommandContext(ctx, h.command, resolvedArgs...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("power-off command for node %q failed: %w (output: %s)", node.Name, err, out)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"testing"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestAllocationBasedUtilPercentCPU(t *testing.T) {
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.Allocatable = &framework.Resource{MilliCPU: 4000}
+	nodeInfo.Requested = &framework.Resource{MilliCPU: 1000}
+
+	if got, want := allocationBasedUtilPercent(nodeInfo, watcher.CPU), 25.0; got != want {
+		t.Errorf("allocationBasedUtilPercent(CPU) = %v, want %v", got, want)
+	}
+}
+
+func TestAllocationBasedUtilPercentMemory(t *testing.T) {
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.Allocatable = &framework.Resource{Memory: 8 * 1024 * 1024 * 1024}
+	nodeInfo.Requested = &framework.Resource{Memory: 2 * 1024 * 1024 * 1024}
+
+	if got, want := allocationBasedUtilPercent(nodeInfo, watcher.Memory), 25.0; got != want {
+		t.Errorf("allocationBasedUtilPercent(Memory) = %v, want %v", got, want)
+	}
+}
+
+func TestAllocationBasedUtilPercentZeroAllocatable(t *testing.T) {
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.Allocatable = &framework.Resource{}
+	nodeInfo.Requested = &framework.Resource{}
+
+	if got := allocationBasedUtilPercent(nodeInfo, watcher.CPU); got != 0 {
+		t.Errorf("allocationBasedUtilPercent(CPU) = %v, want 0 when allocatable is zero", got)
+	}
+	if got := allocationBasedUtilPercent(nodeInfo, watcher.Memory); got != 0 {
+		t.Errorf("allocationBasedUtilPercent(Memory) = %v, want 0 when allocatable is zero", got)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// debugPathPrefix is the path ServeHTTP expects to be mounted at.
+const debugPathPrefix = "/debug/powersaving/"
+
+// nodeScoreDebug records the inputs behind the last Score call made for a
+// node, for the /debug/powersaving/{node} handler.
+type nodeScoreDebug struct {
+	NodeName             string    `json:"nodeName"`
+	Prod                 bool      `json:"prod"`
+	RawCPUUtilPercent    float64   `json:"rawCPUUtilPercent"`
+	MissingCPUUtilMillis int64     `json:"missingCPUUtilMillis"`
+	CPUUtilPercent       float64   `json:"cpuUtilPercent"`
+	Bin                  string    `json:"bin"`
+	Score                int64     `json:"score"`
+	HighCPUThreshold     int64     `json:"highCPUThreshold"`
+	LowCPUThreshold      int64     `json:"lowCPUThreshold"`
+	Timestamp            time.Time `json:"timestamp"`
+}
+
+// recordCPURawUtil stashes the raw (pre-missing-util) CPU utilization percent
+// and the missing-util correction folded in from ScheduledPodsCache, so the
+// debug handler can show both independently of the final blended value
+// resourceUtilPercent returns.
+func (pl *PowerSaving) recordCPURawUtil(nodeName string, rawUtilPercent float64, missingUtilMillis int64) {
+	pl.debugMu.Lock()
+	defer pl.debugMu.Unlock()
+	info := pl.debug[nodeName]
+	info.NodeName = nodeName
+	info.RawCPUUtilPercent = rawUtilPercent
+	info.MissingCPUUtilMillis = missingUtilMillis
+	pl.debug[nodeName] = info
+}
+
+// recordScoreDebug stashes the outcome of a Score call for nodeName.
+func (pl *PowerSaving) recordScoreDebug(nodeName string, prod bool, score int64, bin string, cpuUtilPercent float64, high, low int64) {
+	pl.debugMu.Lock()
+	defer pl.debugMu.Unlock()
+	info := pl.debug[nodeName]
+	info.NodeName = nodeName
+	info.Prod = prod
+	info.Score = score
+	info.Bin = bin
+	info.CPUUtilPercent = cpuUtilPercent
+	info.HighCPUThreshold = high
+	info.LowCPUThreshold = low
+	info.Timestamp = time.Now()
+	pl.debug[nodeName] = info
+}
+
+// ServeHTTP implements http.Handler for GET /debug/powersaving/{node},
+// dumping the inputs behind that node's last Score call: raw CPU
+// utilization, the missing-util correction from ScheduledPodsCache, the
+// chosen bin, the resulting score, and the threshold configuration applied.
+//
+// The framework.Plugin interface has no hook for registering HTTP routes, so
+// this must be mounted explicitly onto the scheduler binary's existing debug
+// mux, e.g. mux.Handle(debugPathPrefix, pl).
+//
+// NOTE: that mounting is not part of this change series and has to land as a
+// follow-up in whichever binary wires up the scheduler's debug mux; until
+// then this handler is reachable only from tests/tools that construct a
+// PowerSaving instance directly and call ServeHTTP themselves.
+func (pl *PowerSaving) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	nodeName := strings.TrimPrefix(r.URL.Path, debugPathPrefix)
+	if nodeName == "" {
+		http.Error(w, "node name is required", http.StatusBadRequest)
+		return
+	}
+
+	pl.debugMu.RLock()
+	info, ok := pl.debug[nodeName]
+	pl.debugMu.RUnlock()
+	if !ok {
+		http.Error(w, "no score recorded for node "+nodeName, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(info)
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultKeplerQuery reads the platform joules counter Kepler exports per
+	// node, falling back to the package joules counter for nodes that only
+	// report the latter. PromQL `or` matches on the full label set excluding
+	// __name__, so wrapping each side in `sum by (instance)` guarantees both
+	// sides key on the same label and the fallback only kicks in when platform
+	// is truly absent for that node, rather than the two silently colliding.
+	// Both are monotonic counters in joules; we only ever look at the delta
+	// between two scrapes.
+	defaultKeplerQuery = `sum by (instance) (kepler_node_platform_joules_total) or sum by (instance) (kepler_node_package_joules_total)`
+	// keplerSampleWindow bounds the ring buffer of (utilization, watts) samples
+	// kept per node for the online least-squares fit.
+	keplerSampleWindow = 60
+	// keplerNodeLabel is the Prometheus label Kepler attaches carrying the node name.
+	keplerNodeLabel = "instance"
+
+	// defaultGPUQuery sums Kepler's per-container GPU joules counter up to the
+	// node level.
+	defaultGPUQuery = `sum by (instance) (kepler_container_gpu_joules_total)`
+	// maxExpectedGPUWatts bounds the GPU power draw used to normalize a node's
+	// GPU energy signal into a 0..100 utilization percent for ResourceWeights.
+	maxExpectedGPUWatts = 300.0
+)
+
+// keplerSample is one (CPU utilization, watts) observation used to fit a node's
+// idle_watts + slope*util power model.
+type keplerSample struct {
+	utilPercent float64
+	watts       float64
+}
+
+// nodePowerModel tracks the running joules counter and the learned affine power
+// model for a single node.
+type nodePowerModel struct {
+	mu sync.Mutex
+
+	haveLast      bool
+	lastJoules    float64
+	lastTimestamp time.Time
+
+	samples []keplerSample
+
+	idleWatts float64
+	slope     float64
+	lastWatts float64
+}
+
+// observe folds a new (timestamp, cumulative joules, utilization) reading into
+// the node's sample ring buffer and refits the power model.
+func (m *nodePowerModel) observe(ts time.Time, joules, utilPercent float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.haveLast {
+		m.haveLast = true
+		m.lastJoules = joules
+		m.lastTimestamp = ts
+		return
+	}
+
+	elapsed := ts.Sub(m.lastTimestamp).Seconds()
+	deltaJoules := joules - m.lastJoules
+	m.lastJoules = joules
+	m.lastTimestamp = ts
+	if elapsed <= 0 || deltaJoules < 0 {
+		return
+	}
+
+	watts := deltaJoules / elapsed
+	m.lastWatts = watts
+	m.samples = append(m.samples, keplerSample{utilPercent: utilPercent, watts: watts})
+	if len(m.samples) > keplerSampleWindow {
+		m.samples = m.samples[len(m.samples)-keplerSampleWindow:]
+	}
+	m.idleWatts, m.slope = fitAffinePowerModel(m.samples)
+}
+
+// lastObservedWatts returns the most recent instantaneous watts rate folded
+// into this model, regardless of whether enough samples exist to trust the
+// fitted idle_watts + slope*util regression.
+func (m *nodePowerModel) lastObservedWatts() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastWatts
+}
+
+// sampleCount returns the number of samples currently backing the fit.
+func (m *nodePowerModel) sampleCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.samples)
+}
+
+// predictWatts returns the modeled power draw at utilPercent.
+func (m *nodePowerModel) predictWatts(utilPercent float64) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.idleWatts + m.slope*utilPercent
+}
+
+// fitAffinePowerModel performs ordinary least squares on (util, watts) samples
+// to estimate the idle_watts + slope*util model. Falls back to a flat model
+// when there isn't enough variance to fit a slope.
+func fitAffinePowerModel(samples []keplerSample) (idleWatts, slope float64) {
+	n := float64(len(samples))
+	if n == 0 {
+		return 0, 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		sumX += s.utilPercent
+		sumY += s.watts
+		sumXY += s.utilPercent * s.watts
+		sumXX += s.utilPercent * s.utilPercent
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return sumY / n, 0
+	}
+	slope = (n*sumXY - sumX*sumY) / denom
+	idleWatts = (sumY - slope*sumX) / n
+	return idleWatts, slope
+}
+
+// KeplerClient polls a Kepler Prometheus endpoint and maintains a per-node
+// affine power model (idle_watts + slope*util%) used to predict the marginal
+// power draw of placing a pod on a node.
+type KeplerClient struct {
+	endpoint       string
+	query          string
+	gpuQuery       string
+	scrapeInterval time.Duration
+	minSamples     int
+
+	idleWattsLabel    string
+	wattsPerCoreLabel string
+
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	models    map[string]*nodePowerModel
+	gpuModels map[string]*nodePowerModel
+}
+
+// NewKeplerClient builds a client for the given Kepler Prometheus endpoint. query
+// may be empty, in which case defaultKeplerQuery is used.
+func NewKeplerClient(endpoint, query string, scrapeInterval time.Duration, minSamples int, idleWattsLabel, wattsPerCoreLabel string) *KeplerClient {
+	if query == "" {
+		query = defaultKeplerQuery
+	}
+	if scrapeInterval <= 0 {
+		scrapeInterval = 30 * time.Second
+	}
+	return &KeplerClient{
+		endpoint:          endpoint,
+		query:             query,
+		gpuQuery:          defaultGPUQuery,
+		scrapeInterval:    scrapeInterval,
+		minSamples:        minSamples,
+		idleWattsLabel:    idleWattsLabel,
+		wattsPerCoreLabel: wattsPerCoreLabel,
+		httpClient:        &http.Client{Timeout: 10 * time.Second},
+		models:            make(map[string]*nodePowerModel),
+		gpuModels:         make(map[string]*nodePowerModel),
+	}
+}
+
+// Run polls Kepler on scrapeInterval until ctx is cancelled.
+func (k *KeplerClient) Run(ctx context.Context, utilByNode func() map[string]float64) {
+	ticker := time.NewTicker(k.scrapeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			util := utilByNode()
+			if err := k.scrapeOnce(ctx, util); err != nil {
+				klog.V(4).ErrorS(err, "Failed to scrape Kepler endpoint", "endpoint", k.endpoint)
+			}
+			if err := k.scrapeGPUOnce(ctx, util); err != nil {
+				klog.V(4).ErrorS(err, "Failed to scrape Kepler GPU joules", "endpoint", k.endpoint)
+			}
+		}
+	}
+}
+
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryInstant runs a Prometheus instant query against the Kepler endpoint and
+// returns the parsed result.
+func (k *KeplerClient) queryInstant(ctx context.Context, query string) (prometheusQueryResponse, error) {
+	var parsed prometheusQueryResponse
+	queryURL := fmt.Sprintf("%s/api/v1/query?query=%s", k.endpoint, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, queryURL, nil)
+	if err != nil {
+		return parsed, err
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return parsed, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return parsed, fmt.Errorf("kepler query returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return parsed, err
+	}
+	return parsed, nil
+}
+
+// scrapeOnce performs a single instant query against Kepler and folds the
+// results into each node's power model. utilByNode supplies the current CPU
+// utilization percent for each node so samples can be correlated.
+func (k *KeplerClient) scrapeOnce(ctx context.Context, utilByNode map[string]float64) error {
+	parsed, err := k.queryInstant(ctx, k.query)
+	if err != nil {
+		return err
+	}
+	k.foldIntoModels(parsed, k.modelFor, utilByNode)
+	return nil
+}
+
+// scrapeGPUOnce mirrors scrapeOnce against the per-node summed GPU joules
+// counter, feeding a separate set of power models used by GPUUtilPercent.
+func (k *KeplerClient) scrapeGPUOnce(ctx context.Context, utilByNode map[string]float64) error {
+	parsed, err := k.queryInstant(ctx, k.gpuQuery)
+	if err != nil {
+		return err
+	}
+	k.foldIntoModels(parsed, k.gpuModelFor, utilByNode)
+	return nil
+}
+
+// foldIntoModels observes each result's (timestamp, cumulative joules) reading
+// into the model returned by modelFor for that node, correlating it against
+// utilByNode's concurrent CPU utilization for callers that fit a regression.
+func (k *KeplerClient) foldIntoModels(parsed prometheusQueryResponse, modelFor func(string) *nodePowerModel, utilByNode map[string]float64) {
+	now := time.Now()
+	for _, result := range parsed.Data.Result {
+		nodeName := result.Metric[keplerNodeLabel]
+		if nodeName == "" {
+			continue
+		}
+		if len(result.Value) != 2 {
+			continue
+		}
+		valueStr, ok := result.Value[1].(string)
+		if !ok {
+			continue
+		}
+		joules, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			continue
+		}
+
+		util := utilByNode[nodeName]
+		model := modelFor(nodeName)
+		model.observe(now, joules, util)
+	}
+}
+
+func (k *KeplerClient) modelFor(nodeName string) *nodePowerModel {
+	return lookupOrCreateModel(&k.mu, k.models, nodeName)
+}
+
+func (k *KeplerClient) gpuModelFor(nodeName string) *nodePowerModel {
+	return lookupOrCreateModel(&k.mu, k.gpuModels, nodeName)
+}
+
+func lookupOrCreateModel(mu *sync.RWMutex, models map[string]*nodePowerModel, nodeName string) *nodePowerModel {
+	mu.RLock()
+	model, ok := models[nodeName]
+	mu.RUnlock()
+	if ok {
+		return model
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if model, ok = models[nodeName]; ok {
+		return model
+	}
+	model = &nodePowerModel{}
+	models[nodeName] = model
+	return model
+}
+
+// GPUUtilPercent reports a node's most recently observed GPU power draw,
+// normalized against maxExpectedGPUWatts into a 0..100 utilization percent
+// suitable for ResourceWeights. Unlike PredictMarginalWatts, this does not fit
+// a CPU-correlated regression: GPU joules are driven by GPU-bound workloads,
+// not general CPU load, so the raw rate is the signal. ok is false until the
+// node has accumulated at least minSamples GPU power samples; the first joules
+// reading only sets the baseline for the next one.
+func (k *KeplerClient) GPUUtilPercent(nodeName string) (float64, bool) {
+	k.mu.RLock()
+	model, exists := k.gpuModels[nodeName]
+	k.mu.RUnlock()
+	if !exists || model.sampleCount() < k.minSamples {
+		return 0, false
+	}
+
+	percent := 100 * model.lastObservedWatts() / maxExpectedGPUWatts
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	return percent, true
+}
+
+// PredictMarginalWatts estimates the power draw node would have after accepting
+// a pod that is predicted to add deltaMillis of CPU on top of currentUtilPercent
+// (out of capMillis total). ok is false when the node has too few samples and no
+// label-based overrides, meaning callers should fall back to the CPU heuristic.
+func (k *KeplerClient) PredictMarginalWatts(node *v1.Node, nodeName string, currentUtilPercent float64, deltaMillis int64, capMillis float64) (watts float64, ok bool) {
+	idleWatts, slope, ok := k.powerModelFor(node, nodeName)
+	if !ok {
+		return 0, false
+	}
+
+	newUtilPercent := currentUtilPercent
+	if capMillis > 0 {
+		newUtilPercent += 100 * float64(deltaMillis) / capMillis
+	}
+	return idleWatts + slope*newUtilPercent, true
+}
+
+// powerModelFor resolves idle_watts/slope for a node, preferring operator
+// supplied node-label overrides, and otherwise requiring the learned model to
+// have accumulated at least minSamples observations.
+func (k *KeplerClient) powerModelFor(node *v1.Node, nodeName string) (idleWatts, slope float64, ok bool) {
+	if node != nil && k.idleWattsLabel != "" && k.wattsPerCoreLabel != "" {
+		idleStr, hasIdle := node.Labels[k.idleWattsLabel]
+		slopeStr, hasSlope := node.Labels[k.wattsPerCoreLabel]
+		if hasIdle && hasSlope {
+			idle, errIdle := strconv.ParseFloat(idleStr, 64)
+			s, errSlope := strconv.ParseFloat(slopeStr, 64)
+			if errIdle == nil && errSlope == nil {
+				return idle, s, true
+			}
+		}
+	}
+
+	k.mu.RLock()
+	model, exists := k.models[nodeName]
+	k.mu.RUnlock()
+	if !exists || model.sampleCount() < k.minSamples {
+		return 0, 0, false
+	}
+	model.mu.Lock()
+	defer model.mu.Unlock()
+	return model.idleWatts, model.slope, true
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitAffinePowerModelNoSamples(t *testing.T) {
+	idleWatts, slope := fitAffinePowerModel(nil)
+	if idleWatts != 0 || slope != 0 {
+		t.Errorf("got (%v, %v), want (0, 0) for no samples", idleWatts, slope)
+	}
+}
+
+func TestFitAffinePowerModelNoVariance(t *testing.T) {
+	samples := []keplerSample{
+		{utilPercent: 50, watts: 100},
+		{utilPercent: 50, watts: 120},
+		{utilPercent: 50, watts: 110},
+	}
+	idleWatts, slope := fitAffinePowerModel(samples)
+	if slope != 0 {
+		t.Errorf("slope = %v, want 0 when all samples share the same utilPercent", slope)
+	}
+	wantIdle := (100.0 + 120.0 + 110.0) / 3
+	if idleWatts != wantIdle {
+		t.Errorf("idleWatts = %v, want mean watts %v", idleWatts, wantIdle)
+	}
+}
+
+func TestFitAffinePowerModelExactLine(t *testing.T) {
+	// watts = 50 + 2*util, sampled exactly: the fit should recover it exactly.
+	samples := []keplerSample{
+		{utilPercent: 0, watts: 50},
+		{utilPercent: 10, watts: 70},
+		{utilPercent: 20, watts: 90},
+		{utilPercent: 30, watts: 110},
+	}
+	idleWatts, slope := fitAffinePowerModel(samples)
+	if math.Abs(idleWatts-50) > 1e-9 {
+		t.Errorf("idleWatts = %v, want 50", idleWatts)
+	}
+	if math.Abs(slope-2) > 1e-9 {
+		t.Errorf("slope = %v, want 2", slope)
+	}
+}
+
+func TestWattsToScore(t *testing.T) {
+	cases := []struct {
+		name  string
+		watts float64
+		want  int64
+	}{
+		{"zero watts gets max score", 0, 100},
+		{"negative watts clamps to max score", -10, 100},
+		{"max expected watts gets min score", maxExpectedWatts, 0},
+		{"over max expected watts clamps to min score", maxExpectedWatts * 2, 0},
+		{"half of max expected watts is mid score", maxExpectedWatts / 2, 50},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := wattsToScore(c.watts); got != c.want {
+				t.Errorf("wattsToScore(%v) = %v, want %v", c.watts, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"sync"
+
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsSubsystem = "scheduler_powersaving"
+
+var (
+	nodeCPUUtilHistogram = k8smetrics.NewHistogram(&k8smetrics.HistogramOpts{
+		Subsystem:      metricsSubsystem,
+		Name:           "node_cpu_util_percent",
+		Help:           "Distribution of node CPU utilization percent observed by the PowerSaving scorer.",
+		Buckets:        []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+		StabilityLevel: k8smetrics.ALPHA,
+	})
+
+	scoreBinTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
+		Subsystem:      metricsSubsystem,
+		Name:           "score_bin_total",
+		Help:           "Count of Score calls by the CPU utilization bin (hot/mid/cold) a node fell into.",
+		StabilityLevel: k8smetrics.ALPHA,
+	}, []string{"bin"})
+
+	nodeScore = k8smetrics.NewGaugeVec(&k8smetrics.GaugeOpts{
+		Subsystem:      metricsSubsystem,
+		Name:           "node_score",
+		Help:           "The score PowerSaving most recently assigned to a node.",
+		StabilityLevel: k8smetrics.ALPHA,
+	}, []string{"node"})
+
+	registerMetricsOnce sync.Once
+)
+
+// registerMetrics registers the PowerSaving metrics with the legacy registry
+// kube-scheduler exposes on /metrics. Safe to call more than once.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(nodeCPUUtilHistogram, scoreBinTotal, nodeScore)
+	})
+}
+
+// classifyBin labels a CPU utilization reading against the high/low thresholds
+// used for a given pod class, for both metrics and debug reporting.
+func classifyBin(nodeCPUUtilPercent float64, high, low int64) string {
+	switch {
+	case nodeCPUUtilPercent >= float64(high):
+		return "hot"
+	case nodeCPUUtilPercent <= float64(low):
+		return "cold"
+	default:
+		return "mid"
+	}
+}
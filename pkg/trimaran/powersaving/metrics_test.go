@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import "testing"
+
+func TestClassifyBin(t *testing.T) {
+	const high, low = int64(80), int64(20)
+
+	cases := []struct {
+		name string
+		util float64
+		want string
+	}{
+		{"above high is hot", 90, "hot"},
+		{"exactly high is hot", 80, "hot"},
+		{"below low is cold", 10, "cold"},
+		{"exactly low is cold", 20, "cold"},
+		{"between low and high is mid", 50, "mid"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := classifyBin(c.util, high, low); got != c.want {
+				t.Errorf("classifyBin(%v, %v, %v) = %q, want %q", c.util, high, low, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// withHostThresholds pins the package-level host CPU thresholds that
+// thresholdsFor reads (set only by New() otherwise) for the duration of a
+// test, restoring the previous values on cleanup.
+func withHostThresholds(t *testing.T, high, low int64) {
+	t.Helper()
+	origHigh, origLow := hostHighCPUThreshold, hostLowCPUThreshold
+	hostHighCPUThreshold, hostLowCPUThreshold = high, low
+	t.Cleanup(func() {
+		hostHighCPUThreshold, hostLowCPUThreshold = origHigh, origLow
+	})
+}
+
+// keplerClientWithGPUSamples builds a KeplerClient whose node has enough GPU
+// joules observations for GPUUtilPercent to report wattsPerSample watts as a
+// percent of maxExpectedGPUWatts.
+func keplerClientWithGPUSamples(t *testing.T, nodeName string, minSamples int, wattsPerSample float64) *KeplerClient {
+	t.Helper()
+	k := NewKeplerClient("http://kepler.example", "", time.Second, minSamples, "", "")
+	model := k.gpuModelFor(nodeName)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	joulesPerSample := wattsPerSample * 30
+	joules := 0.0
+	model.observe(base, joules, 0)
+	for i := 1; i <= minSamples; i++ {
+		joules += joulesPerSample
+		model.observe(base.Add(time.Duration(i)*30*time.Second), joules, 0)
+	}
+	return k
+}
+
+func TestResourceUtilPercentByNameGPU(t *testing.T) {
+	pl := &PowerSaving{kepler: keplerClientWithGPUSamples(t, "node1", 3, 60)}
+
+	percent, ok := pl.resourceUtilPercentByName(gpuEnergyResourceName, "node1", nil, false)
+	if !ok {
+		t.Fatal("resourceUtilPercentByName ok = false, want true once minSamples GPU observations are in")
+	}
+	if want := 100 * 60.0 / maxExpectedGPUWatts; percent != want {
+		t.Errorf("resourceUtilPercentByName(gpu) = %v, want %v", percent, want)
+	}
+}
+
+func TestResourceUtilPercentByNameUnsupported(t *testing.T) {
+	pl := &PowerSaving{}
+	if _, ok := pl.resourceUtilPercentByName(v1.ResourceName("unsupported.example/thing"), "node1", nil, false); ok {
+		t.Error("resourceUtilPercentByName ok = true, want false for an unrecognized ResourceWeights key")
+	}
+}
+
+func TestResourceUtilPercentByNameGPUNoKeplerClient(t *testing.T) {
+	pl := &PowerSaving{}
+	if _, ok := pl.resourceUtilPercentByName(gpuEnergyResourceName, "node1", nil, false); ok {
+		t.Error("resourceUtilPercentByName ok = true, want false when Kepler is disabled")
+	}
+}
+
+func TestMultiResourceScoreWeightsGPUOnly(t *testing.T) {
+	withHostThresholds(t, 80, 20)
+	pl := &PowerSaving{
+		kepler: keplerClientWithGPUSamples(t, "node1", 3, 60),
+		args: &pluginConfig.PowerSavingArgs{
+			ResourceWeights: map[v1.ResourceName]float64{gpuEnergyResourceName: 1},
+		},
+	}
+
+	score, ok := pl.multiResourceScore("node1", nil, false)
+	if !ok {
+		t.Fatal("multiResourceScore ok = false, want true")
+	}
+	gpuUtilPercent := 100 * 60.0 / maxExpectedGPUWatts
+	want := cpuUtilizationScoreWithThresholds(gpuUtilPercent, 80, 20)
+	if score != want {
+		t.Errorf("multiResourceScore = %v, want %v", score, want)
+	}
+}
+
+func TestMultiResourceScoreNoUsableResource(t *testing.T) {
+	pl := &PowerSaving{
+		args: &pluginConfig.PowerSavingArgs{
+			ResourceWeights: map[v1.ResourceName]float64{gpuEnergyResourceName: 1},
+		},
+	}
+	if _, ok := pl.multiResourceScore("node1", nil, false); ok {
+		t.Error("multiResourceScore ok = true, want false when no configured resource yielded a reading")
+	}
+}
+
+func TestMultiResourceScoreIgnoresNonPositiveWeight(t *testing.T) {
+	pl := &PowerSaving{
+		kepler: keplerClientWithGPUSamples(t, "node1", 3, 60),
+		args: &pluginConfig.PowerSavingArgs{
+			ResourceWeights: map[v1.ResourceName]float64{gpuEnergyResourceName: 0},
+		},
+	}
+	if _, ok := pl.multiResourceScore("node1", nil, false); ok {
+		t.Error("multiResourceScore ok = true, want false when the only configured weight is non-positive")
+	}
+}
+
+func TestCPUOrMultiResourceScoreFallsBackWithoutResourceWeights(t *testing.T) {
+	withHostThresholds(t, 80, 20)
+	pl := &PowerSaving{args: &pluginConfig.PowerSavingArgs{}}
+	got := pl.cpuOrMultiResourceScore("node1", nil, false, 50)
+	if want := cpuUtilizationScoreWithThresholds(50, 80, 20); got != want {
+		t.Errorf("cpuOrMultiResourceScore = %v, want %v", got, want)
+	}
+}
+
+func TestCPUOrMultiResourceScoreUsesMultiResourceWhenAvailable(t *testing.T) {
+	withHostThresholds(t, 80, 20)
+	pl := &PowerSaving{
+		kepler: keplerClientWithGPUSamples(t, "node1", 3, 60),
+		args: &pluginConfig.PowerSavingArgs{
+			ResourceWeights: map[v1.ResourceName]float64{gpuEnergyResourceName: 1},
+		},
+	}
+	// nodeCPUUtilPercent of 0 here should be ignored in favor of the
+	// multi-resource (GPU) score once ResourceWeights has a usable reading.
+	got := pl.cpuOrMultiResourceScore("node1", nil, false, 0)
+	gpuUtilPercent := 100 * 60.0 / maxExpectedGPUWatts
+	want := cpuUtilizationScoreWithThresholds(gpuUtilPercent, 80, 20)
+	if got != want {
+		t.Errorf("cpuOrMultiResourceScore = %v, want %v", got, want)
+	}
+}
+
+func TestPredictMemUtilisation(t *testing.T) {
+	origBytes := requestsBytes
+	origMultiplier := requestsMultiplier
+	t.Cleanup(func() {
+		requestsBytes = origBytes
+		requestsMultiplier = origMultiplier
+	})
+
+	t.Run("uses limit when set", func(t *testing.T) {
+		c := &v1.Container{Resources: v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")},
+		}}
+		if got, want := PredictMemUtilisation(c), int64(2*1024*1024*1024); got != want {
+			t.Errorf("PredictMemUtilisation() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("scales request by requestsMultiplier when no limit is set", func(t *testing.T) {
+		requestsMultiplier = 1.5
+		c := &v1.Container{Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1000")},
+		}}
+		if got, want := PredictMemUtilisation(c), int64(1500); got != want {
+			t.Errorf("PredictMemUtilisation() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("falls back to requestsBytes default when neither is set", func(t *testing.T) {
+		requestsBytes = 777
+		c := &v1.Container{}
+		if got, want := PredictMemUtilisation(c), int64(777); got != want {
+			t.Errorf("PredictMemUtilisation() = %v, want %v", got, want)
+		}
+	})
+}
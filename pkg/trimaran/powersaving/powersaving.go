@@ -25,6 +25,9 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 
@@ -42,14 +45,24 @@ const (
 	Name = "PowerSaving"
 	// Time interval in seconds for each metrics agent ingestion.
 	metricsAgentReportingIntervalSeconds = 60
+	// maxExpectedWatts bounds the Kepler-predicted marginal power draw used to
+	// normalize a node into the 0..MaxNodeScore range.
+	maxExpectedWatts = 500.0
 )
 
 var (
 	requestsMilliCores   = v1beta2.DefaultRequestsMilliCores
+	requestsBytes        = v1beta2.DefaultRequestsBytes
 	hostLowCPUThreshold  = v1beta2.DefaultLowCPUThreshold
 	hostHighCPUThreshold = v1beta2.DefaultHighCPUThreshold
 	requestsMultiplier   float64
 	keplerEnabled        = false
+
+	// prodHighCPUThreshold and prodLowCPUThreshold are the stricter thresholds
+	// applied to Prod pods, so a spiky node isn't picked for a latency-sensitive
+	// workload. They default to the non-Prod thresholds when both are unset.
+	prodHighCPUThreshold = v1beta2.DefaultHighCPUThreshold
+	prodLowCPUThreshold  = v1beta2.DefaultLowCPUThreshold
 )
 
 type PowerSaving struct {
@@ -57,10 +70,17 @@ type PowerSaving struct {
 	eventHandler *trimaran.PodAssignEventHandler
 	collector    *trimaran.Collector
 	args         *pluginConfig.PowerSavingArgs
+	kepler       *KeplerClient
+
+	// debugMu guards debug, the per-node snapshot of the last Score call's
+	// inputs served by ServeHTTP.
+	debugMu sync.RWMutex
+	debug   map[string]nodeScoreDebug
 }
 
 func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the PowerSaving plugin")
+	registerMetrics()
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.PowerSavingArgs)
 	if !ok {
@@ -83,22 +103,85 @@ func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		hostLowCPUThreshold = hostHighCPUThreshold
 	}
 
+	prodHighCPUThreshold = args.ProdHighCPUThreshold
+	prodLowCPUThreshold = args.ProdLowCPUThreshold
+	if prodHighCPUThreshold == 0 && prodLowCPUThreshold == 0 {
+		prodHighCPUThreshold = hostHighCPUThreshold
+		prodLowCPUThreshold = hostLowCPUThreshold
+	}
+	if prodHighCPUThreshold > framework.MaxNodeScore {
+		prodHighCPUThreshold = framework.MaxNodeScore
+	}
+	if prodLowCPUThreshold < framework.MinNodeScore {
+		prodLowCPUThreshold = framework.MinNodeScore
+	}
+	if prodLowCPUThreshold > prodHighCPUThreshold {
+		prodLowCPUThreshold = prodHighCPUThreshold
+	}
+
+	if args.RequestsMilliCores > 0 {
+		requestsMilliCores = args.RequestsMilliCores
+	}
+	if args.RequestsMultiplier > 0 {
+		requestsMultiplier = args.RequestsMultiplier
+	}
+
 	klog.V(4).InfoS("Using PowerSavingArgs",
 		"hostLowCPUThreshold", hostLowCPUThreshold,
-		"hostHighCPUThreshold", hostHighCPUThreshold)
+		"hostHighCPUThreshold", hostHighCPUThreshold,
+		"prodLowCPUThreshold", prodLowCPUThreshold,
+		"prodHighCPUThreshold", prodHighCPUThreshold)
 
 	podAssignEventHandler := trimaran.New()
 	podAssignEventHandler.AddToHandle(handle)
 
+	keplerEnabled = args.KeplerEnabled
+
 	pl := &PowerSaving{
 		handle:       handle,
 		eventHandler: podAssignEventHandler,
 		collector:    collector,
 		args:         args,
+		debug:        make(map[string]nodeScoreDebug),
+	}
+
+	if keplerEnabled {
+		if args.KeplerEndpoint == "" {
+			return nil, fmt.Errorf("KeplerEnabled is true but KeplerEndpoint is not set")
+		}
+		scrapeInterval := time.Duration(args.KeplerScrapeIntervalSeconds) * time.Second
+		minSamples := int(args.KeplerMinSamples)
+		if minSamples <= 0 {
+			minSamples = int(v1beta2.DefaultKeplerMinSamples)
+		}
+		pl.kepler = NewKeplerClient(args.KeplerEndpoint, args.KeplerQuery, scrapeInterval, minSamples, args.NodeIdleWattsLabel, args.NodeWattsPerCoreLabel)
+		go pl.kepler.Run(context.Background(), pl.nodeUtilSnapshot)
 	}
+
 	return pl, nil
 }
 
+// nodeUtilSnapshot returns the latest known CPU utilization percent for every
+// node in the current snapshot, used by the Kepler client to correlate power
+// samples with utilization.
+func (pl *PowerSaving) nodeUtilSnapshot() map[string]float64 {
+	result := make(map[string]float64)
+	nodeInfos, err := pl.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return result
+	}
+	for _, nodeInfo := range nodeInfos {
+		nodeName := nodeInfo.Node().Name
+		metrics, _ := pl.collector.GetNodeMetrics(nodeName)
+		for _, metric := range metrics {
+			if metric.Type == watcher.CPU && (metric.Operator == watcher.Average || metric.Operator == watcher.Latest) {
+				result[nodeName] = metric.Value
+			}
+		}
+	}
+	return result
+}
+
 func (pl *PowerSaving) Name() string {
 	return Name
 }
@@ -110,37 +193,196 @@ func (pl *PowerSaving) Score(ctx context.Context, cycleState *framework.CycleSta
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 
-	// get node metrics
-	metrics, allMetrics := pl.collector.GetNodeMetrics(nodeName)
-	if metrics == nil {
+	prod := isPodProd(pod, pl.args)
+	nodeCPUUtilPercent, ok := pl.nodeCPUUtilPercent(nodeName, nodeInfo, prod)
+	if !ok {
 		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
 		// Avoid the node by scoring minimum
 		return score, nil
 		// TODO(aqadeer): If this happens for a long time, fall back to allocation based packing. This could mean maintaining failure state across cycles if scheduler doesn't provide this state
+	}
+	nodeCPUCapMillis := float64(nodeInfo.Node().Status.Capacity.Cpu().MilliValue())
+
+	if keplerEnabled && pl.kepler != nil {
+		var predictedDeltaMillis int64
+		for i := range pod.Spec.Containers {
+			predictedDeltaMillis += PredictUtilisation(&pod.Spec.Containers[i])
+		}
+		predictedDeltaMillis += pod.Spec.Overhead.Cpu().MilliValue()
+
+		if marginalWatts, ok := pl.kepler.PredictMarginalWatts(nodeInfo.Node(), nodeName, nodeCPUUtilPercent, predictedDeltaMillis, nodeCPUCapMillis); ok {
+			score = wattsToScore(marginalWatts)
+			klog.V(6).InfoS("Kepler-predicted marginal power for host", "nodeName", nodeName, "marginalWatts", marginalWatts, "score", score)
+		} else {
+			klog.V(4).InfoS("Kepler metrics unavailable or insufficient samples for node; falling back to CPU heuristic", "nodeName", nodeName)
+			score = pl.cpuOrMultiResourceScore(nodeName, nodeInfo, prod, nodeCPUUtilPercent)
+		}
+	} else {
+		// BE/best-effort pods keep the "prefer hot nodes" behavior so they pack
+		// onto already-warm hardware; Prod pods use the stricter thresholds.
+		score = pl.cpuOrMultiResourceScore(nodeName, nodeInfo, prod, nodeCPUUtilPercent)
+	}
+
+	high, low := thresholdsFor(prod)
+	bin := classifyBin(nodeCPUUtilPercent, high, low)
+	nodeCPUUtilHistogram.Observe(nodeCPUUtilPercent)
+	scoreBinTotal.WithLabelValues(bin).Inc()
+	nodeScore.WithLabelValues(nodeName).Set(float64(score))
+	pl.recordScoreDebug(nodeName, prod, score, bin, nodeCPUUtilPercent, high, low)
+
+	klog.V(6).InfoS("Score for host", "nodeName", nodeName, "score", score, "prod", prod, "bin", bin)
+	return score, framework.NewStatus(framework.Success, "")
+}
+
+// Filter hard-rejects nodes whose metrics are stale when StrictFreshness is
+// enabled, and nodes exceeding ProdHighCPUThreshold for Prod pods, so that
+// neither case is merely deprioritized but never landed on in the first
+// place. BE/best-effort pods are never filtered on CPU thresholds here.
+func (pl *PowerSaving) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	nodeName := nodeInfo.Node().Name
+
+	if pl.args.StrictFreshness && pl.nodeMetricsStale(nodeName) {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(
+			"node %q metrics are older than NodeMetricExpirationSeconds (%ds); skipping due to StrictFreshness",
+			nodeName, pl.metricExpirationSeconds()))
+	}
+
+	if !isPodProd(pod, pl.args) {
+		return framework.NewStatus(framework.Success, "")
+	}
+
+	nodeCPUUtilPercent, ok := pl.nodeCPUUtilPercent(nodeName, nodeInfo, true)
+	if !ok {
+		return framework.NewStatus(framework.Success, "")
+	}
+	if nodeCPUUtilPercent > float64(prodHighCPUThreshold) {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(
+			"node %q CPU utilization %.1f%% exceeds ProdHighCPUThreshold %d for a Prod pod",
+			nodeName, nodeCPUUtilPercent, prodHighCPUThreshold))
+	}
+	return framework.NewStatus(framework.Success, "")
+}
+
+// Reserve fires the PreferWarmNode Event for the node the scheduler actually
+// committed to, using the bin recorded by the Score call that produced that
+// decision. Score itself cannot emit this Event: it runs once per candidate
+// node a pod is scored against, so emitting there would fire once per
+// candidate instead of once for the node actually chosen.
+func (pl *PowerSaving) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	pl.debugMu.RLock()
+	info, ok := pl.debug[nodeName]
+	pl.debugMu.RUnlock()
+	if !ok || info.Bin != "cold" {
+		return nil
+	}
+
+	nodeInfo, err := pl.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return nil
+	}
+	pl.handle.EventRecorder().Eventf(pod, nodeInfo.Node(), v1.EventTypeNormal, "PreferWarmNode", "Scoring",
+		"node %q preferred by PowerSaving: CPU utilization %.1f%% is at or below LowCPUThreshold %d", nodeName, info.CPUUtilPercent, info.LowCPUThreshold)
+	return nil
+}
+
+// Unreserve is a no-op: Reserve only emits an Event and holds no state that
+// needs to be rolled back if a later plugin rejects the node.
+func (pl *PowerSaving) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+}
 
+// thresholdsFor returns the (high, low) CPU thresholds to score nodeCPUUtilPercent
+// against for a Prod or BE/best-effort pod.
+func thresholdsFor(prod bool) (high, low int64) {
+	if prod {
+		return prodHighCPUThreshold, prodLowCPUThreshold
 	}
+	return hostHighCPUThreshold, hostLowCPUThreshold
+}
+
+// nodeCPUUtilPercent computes a node's CPU utilization percent. When
+// useAggregated is true and args.AggregatedUsage is set, the aggregated
+// percentile reading (e.g. p95) is used as the base sample instead of the
+// latest/average one, so a spiky node isn't picked for a Prod pod.
+func (pl *PowerSaving) nodeCPUUtilPercent(nodeName string, nodeInfo *framework.NodeInfo, useAggregated bool) (float64, bool) {
+	return pl.resourceUtilPercent(resourceSignal{
+		resourceType: watcher.CPU,
+		predict:      PredictUtilisation,
+		overhead:     func(rl v1.ResourceList) int64 { return rl.Cpu().MilliValue() },
+		capacity:     func(node *v1.Node) int64 { return node.Status.Capacity.Cpu().MilliValue() },
+	}, nodeName, nodeInfo, useAggregated)
+}
+
+// nodeMemoryUtilPercent mirrors nodeCPUUtilPercent for memory.
+func (pl *PowerSaving) nodeMemoryUtilPercent(nodeName string, nodeInfo *framework.NodeInfo, useAggregated bool) (float64, bool) {
+	return pl.resourceUtilPercent(resourceSignal{
+		resourceType: watcher.Memory,
+		predict:      PredictMemUtilisation,
+		overhead:     func(rl v1.ResourceList) int64 { return rl.Memory().Value() },
+		capacity:     func(node *v1.Node) int64 { return node.Status.Capacity.Memory().Value() },
+	}, nodeName, nodeInfo, useAggregated)
+}
+
+// resourceSignal parameterizes resourceUtilPercent over a single resource
+// dimension (CPU, memory, ...): how to predict a container's usage of it, how
+// to read a pod's overhead for it, and how to read a node's capacity for it.
+type resourceSignal struct {
+	resourceType watcher.ResourceType
+	predict      func(*v1.Container) int64
+	overhead     func(v1.ResourceList) int64
+	capacity     func(*v1.Node) int64
+}
 
-	var nodeCPUUtilPercent float64
-	var cpuMetricFound bool
+// resourceUtilPercent computes a node's utilization percent for a single
+// resource dimension, folding in utilization predicted for pods that were
+// scheduled too recently to show up in the fetched metrics window yet. When
+// useAggregated is true and args.AggregatedUsage is set, the aggregated
+// percentile reading (e.g. p95) is used as the base sample instead of the
+// latest/average one, so a spiky node isn't picked for a Prod pod.
+func (pl *PowerSaving) resourceUtilPercent(signal resourceSignal, nodeName string, nodeInfo *framework.NodeInfo, useAggregated bool) (float64, bool) {
+	metrics, allMetrics := pl.collector.GetNodeMetrics(nodeName)
+	if metrics == nil {
+		return 0, false
+	}
+
+	if pl.metricsExpired(allMetrics.Window.End) {
+		if pl.args.FallbackToRequests {
+			klog.V(4).InfoS("Node metrics window expired; falling back to allocation-based packing", "nodeName", nodeName, "resourceType", signal.resourceType)
+			return allocationBasedUtilPercent(nodeInfo, signal.resourceType), true
+		}
+		klog.V(4).InfoS("Node metrics window expired; treating node as having no metrics", "nodeName", nodeName, "resourceType", signal.resourceType)
+		return 0, false
+	}
+
+	aggregatedOperator := watcher.OperatorType(strings.ToUpper(pl.args.AggregatedUsage))
+	var utilPercent float64
+	var metricFound bool
 	for _, metric := range metrics {
-		if metric.Type == watcher.CPU {
-			if metric.Operator == watcher.Average || metric.Operator == watcher.Latest {
-				nodeCPUUtilPercent = metric.Value
-				cpuMetricFound = true
+		if metric.Type != signal.resourceType {
+			continue
+		}
+		if useAggregated && pl.args.AggregatedUsage != "" {
+			if metric.Operator == aggregatedOperator {
+				utilPercent = metric.Value
+				metricFound = true
 			}
+			continue
+		}
+		if metric.Operator == watcher.Average || metric.Operator == watcher.Latest {
+			utilPercent = metric.Value
+			metricFound = true
 		}
 	}
 
-	if !cpuMetricFound {
-		klog.ErrorS(nil, "Cpu metric not found in node metrics", "nodeName", nodeName, "nodeMetrics", metrics)
-		return score, nil
+	if !metricFound {
+		klog.V(4).InfoS("Metric not found in node metrics", "nodeName", nodeName, "resourceType", signal.resourceType, "nodeMetrics", metrics)
+		return 0, false
 	}
-	nodeCPUCapMillis := float64(nodeInfo.Node().Status.Capacity.Cpu().MilliValue())
-	nodeCPUUtilMillis := (nodeCPUUtilPercent / 100) * nodeCPUCapMillis
+	capMillis := float64(signal.capacity(nodeInfo.Node()))
+	utilMillis := (utilPercent / 100) * capMillis
 
-	klog.V(6).InfoS("Calculating CPU utilization and capacity", "nodeName", nodeName, "cpuUtilMillis", nodeCPUUtilMillis, "cpuCapMillis", nodeCPUCapMillis)
+	klog.V(6).InfoS("Calculating utilization and capacity", "nodeName", nodeName, "resourceType", signal.resourceType, "utilMillis", utilMillis, "capMillis", capMillis)
 
-	var missingCPUUtilMillis int64 = 0
+	var missingUtilMillis int64 = 0
 	pl.eventHandler.RLock()
 	for _, info := range pl.eventHandler.ScheduledPodsCache[nodeName] {
 		// If the time stamp of the scheduled pod is outside fetched metrics window, or it is within metrics reporting interval seconds, we predict util.
@@ -150,38 +392,154 @@ func (pl *PowerSaving) Score(ctx context.Context, cycleState *framework.CycleSta
 		if info.Timestamp.Unix() > allMetrics.Window.End || info.Timestamp.Unix() <= allMetrics.Window.End &&
 			(allMetrics.Window.End-info.Timestamp.Unix()) < metricsAgentReportingIntervalSeconds {
 			for _, container := range info.Pod.Spec.Containers {
-				missingCPUUtilMillis += PredictUtilisation(&container)
+				missingUtilMillis += signal.predict(&container)
 			}
-			missingCPUUtilMillis += info.Pod.Spec.Overhead.Cpu().MilliValue()
-			klog.V(6).InfoS("Missing utilization for pod", "podName", info.Pod.Name, "missingCPUUtilMillis", missingCPUUtilMillis)
+			missingUtilMillis += signal.overhead(info.Pod.Spec.Overhead)
+			klog.V(6).InfoS("Missing utilization for pod", "podName", info.Pod.Name, "resourceType", signal.resourceType, "missingUtilMillis", missingUtilMillis)
 		}
 	}
 	pl.eventHandler.RUnlock()
-	klog.V(6).InfoS("Missing utilization for node", "nodeName", nodeName, "missingCPUUtilMillis", missingCPUUtilMillis)
+	klog.V(6).InfoS("Missing utilization for node", "nodeName", nodeName, "resourceType", signal.resourceType, "missingUtilMillis", missingUtilMillis)
 
-	if nodeCPUCapMillis != 0 {
-		nodeCPUUtilPercent = 100 * (nodeCPUUtilMillis + float64(missingCPUUtilMillis)) / nodeCPUCapMillis
+	if signal.resourceType == watcher.CPU {
+		pl.recordCPURawUtil(nodeName, utilPercent, missingUtilMillis)
 	}
 
-	if keplerEnabled {
+	if capMillis != 0 {
+		utilPercent = 100 * (utilMillis + float64(missingUtilMillis)) / capMillis
+	}
+	return utilPercent, true
+}
 
-	} else {
-		/* choose nodes by cpu utilization:
-		 * 1. bigger than hostHighCPUThreshold
-		 * 2. smaller hostLowCPUThreshold
-		 * 3. between hostHighCPUThreshold and hostLowCPUThreshold
-		 */
-		if nodeCPUUtilPercent >= float64(hostHighCPUThreshold) {
-			score = int64(math.Round(nodeCPUUtilPercent))
-		} else if nodeCPUUtilPercent <= float64(hostLowCPUThreshold) {
-			score = int64(math.Round(nodeCPUUtilPercent + float64(hostHighCPUThreshold-hostLowCPUThreshold)))
-		} else {
-			score = int64(math.Round(nodeCPUUtilPercent - float64(hostLowCPUThreshold)))
+// gpuEnergyResourceName is the pseudo ResourceName operators add to
+// ResourceWeights to factor Kepler-reported GPU energy into the score.
+const gpuEnergyResourceName = v1.ResourceName("power.kepler.io/gpu-joules")
+
+// multiResourceScore combines per-resource normalized utilizations named in
+// args.ResourceWeights into one weighted score, using the same three-band bin
+// logic as cpuUtilizationScoreWithThresholds for each resource. ok is false
+// when none of the configured resources yielded a usable reading.
+//
+// NIC bandwidth from node-exporter is not wired in yet; ResourceWeights
+// entries other than cpu/memory/GPU energy are ignored for now.
+func (pl *PowerSaving) multiResourceScore(nodeName string, nodeInfo *framework.NodeInfo, prod bool) (int64, bool) {
+	high, low := thresholdsFor(prod)
+
+	var totalWeight, weightedScore float64
+	for resourceName, weight := range pl.args.ResourceWeights {
+		if weight <= 0 {
+			continue
 		}
+		util, ok := pl.resourceUtilPercentByName(resourceName, nodeName, nodeInfo, prod)
+		if !ok {
+			continue
+		}
+		weightedScore += weight * float64(cpuUtilizationScoreWithThresholds(util, high, low))
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0, false
 	}
+	return int64(math.Round(weightedScore / totalWeight)), true
+}
 
-	klog.V(6).InfoS("Score for host", "nodeName", nodeName, "score", score)
-	return score, framework.NewStatus(framework.Success, "")
+// resourceUtilPercentByName dispatches to the right utilization source for a
+// ResourceWeights key.
+func (pl *PowerSaving) resourceUtilPercentByName(resourceName v1.ResourceName, nodeName string, nodeInfo *framework.NodeInfo, prod bool) (float64, bool) {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return pl.nodeCPUUtilPercent(nodeName, nodeInfo, prod)
+	case v1.ResourceMemory:
+		return pl.nodeMemoryUtilPercent(nodeName, nodeInfo, prod)
+	case gpuEnergyResourceName:
+		if pl.kepler == nil {
+			return 0, false
+		}
+		return pl.kepler.GPUUtilPercent(nodeName)
+	default:
+		klog.V(4).InfoS("Unsupported resource in ResourceWeights; ignoring", "resource", resourceName)
+		return 0, false
+	}
+}
+
+// cpuOrMultiResourceScore uses the weighted multi-resource score when
+// ResourceWeights is configured, falling back to the plain CPU-utilization
+// heuristic otherwise (or if none of the configured resources had data).
+func (pl *PowerSaving) cpuOrMultiResourceScore(nodeName string, nodeInfo *framework.NodeInfo, prod bool, nodeCPUUtilPercent float64) int64 {
+	if len(pl.args.ResourceWeights) > 0 {
+		if score, ok := pl.multiResourceScore(nodeName, nodeInfo, prod); ok {
+			return score
+		}
+	}
+	high, low := thresholdsFor(prod)
+	return cpuUtilizationScoreWithThresholds(nodeCPUUtilPercent, high, low)
+}
+
+// metricExpirationSeconds returns the effective NodeMetricExpirationSeconds,
+// falling back to the default when unset.
+func (pl *PowerSaving) metricExpirationSeconds() int64 {
+	if pl.args.NodeMetricExpirationSeconds > 0 {
+		return pl.args.NodeMetricExpirationSeconds
+	}
+	return v1beta2.DefaultNodeMetricExpirationSeconds
+}
+
+// metricsExpired reports whether a metrics window ending at windowEnd is
+// older than NodeMetricExpirationSeconds.
+func (pl *PowerSaving) metricsExpired(windowEnd int64) bool {
+	return time.Now().Unix()-windowEnd > pl.metricExpirationSeconds()
+}
+
+// nodeMetricsStale reports whether nodeName has no metrics at all, or a
+// metrics window older than NodeMetricExpirationSeconds.
+func (pl *PowerSaving) nodeMetricsStale(nodeName string) bool {
+	metrics, allMetrics := pl.collector.GetNodeMetrics(nodeName)
+	if metrics == nil {
+		return true
+	}
+	return pl.metricsExpired(allMetrics.Window.End)
+}
+
+// allocationBasedUtilPercent estimates CPU or memory utilization from summed pod
+// requests over node allocatable, used when a node's real metrics have
+// expired but FallbackToRequests is enabled.
+func allocationBasedUtilPercent(nodeInfo *framework.NodeInfo, resourceType watcher.ResourceType) float64 {
+	if resourceType == watcher.Memory {
+		if nodeInfo.Allocatable.Memory == 0 {
+			return 0
+		}
+		return 100 * float64(nodeInfo.Requested.Memory) / float64(nodeInfo.Allocatable.Memory)
+	}
+	if nodeInfo.Allocatable.MilliCPU == 0 {
+		return 0
+	}
+	return 100 * float64(nodeInfo.Requested.MilliCPU) / float64(nodeInfo.Allocatable.MilliCPU)
+}
+
+// cpuUtilizationScoreWithThresholds scores a node purely by its CPU
+// utilization percent against the given high/low thresholds:
+// 1. at or above high
+// 2. at or below low
+// 3. in between high and low
+func cpuUtilizationScoreWithThresholds(nodeCPUUtilPercent float64, high, low int64) int64 {
+	if nodeCPUUtilPercent >= float64(high) {
+		return int64(math.Round(nodeCPUUtilPercent))
+	} else if nodeCPUUtilPercent <= float64(low) {
+		return int64(math.Round(nodeCPUUtilPercent + float64(high-low)))
+	}
+	return int64(math.Round(nodeCPUUtilPercent - float64(low)))
+}
+
+// wattsToScore inverts a Kepler-predicted marginal power draw into the
+// 0..MaxNodeScore range so the node with the lowest predicted power wins.
+func wattsToScore(watts float64) int64 {
+	if watts < 0 {
+		watts = 0
+	}
+	if watts > maxExpectedWatts {
+		watts = maxExpectedWatts
+	}
+	return int64(math.Round(float64(framework.MaxNodeScore) - (watts/maxExpectedWatts)*float64(framework.MaxNodeScore)))
 }
 
 func (pl *PowerSaving) ScoreExtensions() framework.ScoreExtensions {
@@ -210,3 +568,14 @@ func PredictUtilisation(container *v1.Container) int64 {
 		return requestsMilliCores
 	}
 }
+
+// PredictMemUtilisation mirrors PredictUtilisation for memory, in bytes.
+func PredictMemUtilisation(container *v1.Container) int64 {
+	if _, ok := container.Resources.Limits[v1.ResourceMemory]; ok {
+		return container.Resources.Limits.Memory().Value()
+	} else if _, ok := container.Resources.Requests[v1.ResourceMemory]; ok {
+		return int64(math.Round(float64(container.Resources.Requests.Memory().Value()) * requestsMultiplier))
+	} else {
+		return requestsBytes
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// isPodProd reports whether pod should be scored with the stricter Prod
+// thresholds rather than the default ones. A pod is Prod when its
+// PriorityClassName is listed in args.ProdPriorityClasses, or, failing that,
+// when it carries the configured Prod label.
+func isPodProd(pod *v1.Pod, args *pluginConfig.PowerSavingArgs) bool {
+	for _, priorityClass := range args.ProdPriorityClasses {
+		if pod.Spec.PriorityClassName == priorityClass {
+			return true
+		}
+	}
+	if args.ProdLabelKey == "" {
+		return false
+	}
+	return pod.Labels[args.ProdLabelKey] == args.ProdLabelValue
+}
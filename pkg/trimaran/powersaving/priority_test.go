@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestIsPodProd(t *testing.T) {
+	args := &pluginConfig.PowerSavingArgs{
+		ProdPriorityClasses: []string{"prod-critical"},
+		ProdLabelKey:        "tier",
+		ProdLabelValue:      "prod",
+	}
+
+	cases := []struct {
+		name string
+		pod  *v1.Pod
+		want bool
+	}{
+		{
+			name: "matches priority class",
+			pod:  &v1.Pod{Spec: v1.PodSpec{PriorityClassName: "prod-critical"}},
+			want: true,
+		},
+		{
+			name: "matches label when priority class doesn't match",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "prod"}}},
+			want: true,
+		},
+		{
+			name: "neither priority class nor label match",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "be"}}},
+			want: false,
+		},
+		{
+			name: "no priority class and no labels at all",
+			pod:  &v1.Pod{},
+			want: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isPodProd(c.pod, args); got != c.want {
+				t.Errorf("isPodProd() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIsPodProdNoLabelKeyConfigured(t *testing.T) {
+	args := &pluginConfig.PowerSavingArgs{}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "prod"}}}
+	if isPodProd(pod, args) {
+		t.Error("isPodProd() = true, want false when ProdLabelKey is unset regardless of pod labels")
+	}
+}
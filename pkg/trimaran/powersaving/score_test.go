@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package powersaving
+
+import "testing"
+
+func TestCPUUtilizationScoreWithThresholds(t *testing.T) {
+	const high, low = int64(80), int64(20)
+
+	cases := []struct {
+		name string
+		util float64
+		want int64
+	}{
+		{"at or above high is scored as-is", 90, 90},
+		{"exactly high is scored as-is", 80, 80},
+		{"at or below low is boosted by the high-low spread", 10, 10 + (high - low)},
+		{"exactly low is boosted by the high-low spread", 20, 20 + (high - low)},
+		{"in between is scored relative to low", 50, 50 - low},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := cpuUtilizationScoreWithThresholds(c.util, high, low); got != c.want {
+				t.Errorf("cpuUtilizationScoreWithThresholds(%v, %v, %v) = %v, want %v", c.util, high, low, got, c.want)
+			}
+		})
+	}
+}